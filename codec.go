@@ -0,0 +1,85 @@
+// Copyright © SurrealDB Ltd
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blist
+
+import (
+	"github.com/golang/snappy"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Codec encodes item values before they are stored in a List, and decodes
+// them back when an item's value is read.
+type Codec interface {
+	Encode(val []byte) []byte
+	Decode(val []byte) ([]byte, error)
+}
+
+// NoopCodec performs no transformation. It is the default Codec used by
+// New when no WithCodec option is given.
+type NoopCodec struct{}
+
+// Encode returns val unchanged.
+func (NoopCodec) Encode(val []byte) []byte { return val }
+
+// Decode returns val unchanged.
+func (NoopCodec) Decode(val []byte) ([]byte, error) { return val, nil }
+
+// SnappyCodec compresses item values using snappy.
+type SnappyCodec struct{}
+
+// Encode compresses val using snappy.
+func (SnappyCodec) Encode(val []byte) []byte {
+	return snappy.Encode(nil, val)
+}
+
+// Decode decompresses val using snappy.
+func (SnappyCodec) Decode(val []byte) ([]byte, error) {
+	return snappy.Decode(nil, val)
+}
+
+// ZstdCodec compresses item values using zstd. Unlike NoopCodec and
+// SnappyCodec, a ZstdCodec holds reusable encoder and decoder state, so it
+// must be constructed with NewZstdCodec rather than used as a zero value.
+type ZstdCodec struct {
+	enc *zstd.Encoder
+	dec *zstd.Decoder
+}
+
+// NewZstdCodec creates a ZstdCodec with a reusable encoder and decoder.
+func NewZstdCodec() (*ZstdCodec, error) {
+
+	enc, err := zstd.NewWriter(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	dec, err := zstd.NewReader(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ZstdCodec{enc: enc, dec: dec}, nil
+
+}
+
+// Encode compresses val using zstd.
+func (c *ZstdCodec) Encode(val []byte) []byte {
+	return c.enc.EncodeAll(val, nil)
+}
+
+// Decode decompresses val using zstd.
+func (c *ZstdCodec) Decode(val []byte) ([]byte, error) {
+	return c.dec.DecodeAll(val, nil)
+}
@@ -0,0 +1,73 @@
+// Copyright © SurrealDB Ltd
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blist
+
+import "testing"
+
+// countingCodec wraps SnappyCodec and counts Decode calls, so tests can
+// assert that Item.Val caches its result rather than decoding every call.
+type countingCodec struct {
+	SnappyCodec
+	decodes *int
+}
+
+func (c countingCodec) Decode(val []byte) ([]byte, error) {
+	*c.decodes++
+	return c.SnappyCodec.Decode(val)
+}
+
+// TestValCachesDecode exercises the lazy-decode path taken by an item
+// that only has its encoded bytes set, such as one freshly loaded from a
+// Store snapshot (see List.putRaw): the first Val() call should decode,
+// and every subsequent call should reuse the cached result.
+func TestValCachesDecode(t *testing.T) {
+
+	decodes := 0
+	codec := countingCodec{decodes: &decodes}
+
+	plain := []byte("hello world hello world hello world")
+
+	l := New(WithCodec(codec))
+	i := l.putRaw(1, codec.Encode(plain))
+
+	for n := 0; n < 5; n++ {
+		if string(i.Val()) != string(plain) {
+			t.Fatalf("Val() returned unexpected content on call %d", n)
+		}
+	}
+
+	if decodes != 1 {
+		t.Fatalf("codec Decode called %d times, want 1 (Val should cache)", decodes)
+	}
+
+}
+
+func TestRawValSkipsDecode(t *testing.T) {
+
+	decodes := 0
+	codec := countingCodec{decodes: &decodes}
+
+	l := New(WithCodec(codec))
+
+	i := l.Put(1, []byte("hello world hello world hello world"))
+
+	_ = i.RawVal()
+	_ = i.RawVal()
+
+	if decodes != 0 {
+		t.Fatalf("codec Decode called %d times, want 0 (RawVal should not decode)", decodes)
+	}
+
+}
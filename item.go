@@ -14,13 +14,18 @@
 
 package blist
 
+import "sync"
 import "github.com/google/btree"
 
 // Item represents an item in an in-memory btree.
 type Item struct {
-	ver  uint64
-	val  []byte
-	list *List
+	ver   int64
+	raw   []byte
+	dec   []byte
+	once  sync.Once
+	codec Codec
+	list  *List
+	tx    *Tx
 }
 
 // Less determines whether an item precedes another item in the list.
@@ -29,43 +34,99 @@ func (i *Item) Less(than btree.Item) bool {
 }
 
 // Ver returns the version of this item in the containing list.
-func (i *Item) Ver() uint64 {
+func (i *Item) Ver() int64 {
 	return i.ver
 }
 
-// Val returns the value of this item in the containing list.
+// Val returns the decoded value of this item. The value is decoded using
+// the containing list's Codec the first time Val is called, and the
+// decoded form is then cached on the item for every subsequent call.
 func (i *Item) Val() []byte {
-	return i.val
+
+	i.once.Do(func() {
+		if i.codec != nil {
+			i.dec, _ = i.codec.Decode(i.raw)
+		} else {
+			i.dec = i.raw
+		}
+	})
+
+	return i.dec
+
 }
 
-// Set updates the value of this item in the containing list.
+// RawVal returns the still-encoded, on-wire value of this item, without
+// triggering a decode. This lets a caller, such as a network writer or a
+// persistence layer, forward the value on without a decode/re-encode
+// round trip.
+func (i *Item) RawVal() []byte {
+	return i.raw
+}
+
+// Set updates the value of this item in the containing list, encoding it
+// using the containing list's Codec.
 func (i *Item) Set(val []byte) *Item {
-	i.val = val
+
+	if i.codec != nil {
+		i.raw = i.codec.Encode(val)
+	} else {
+		i.raw = val
+	}
+
+	i.dec = val
+	i.once.Do(func() {})
+
 	return i
+
 }
 
-// Del deletes the item from any containing list and returns it.
+// Del deletes the item from its containing list or transaction, and
+// returns it. An item obtained from a transaction (List.View or
+// List.Update) is bound to that transaction's own snapshot, not the live
+// list, so deleting it never reaches through to the live list: within a
+// View it is a no-op, since the transaction cannot be written to, and
+// within an Update it is equivalent to calling Tx.Del.
 func (i *Item) Del() *Item {
 
-	if i.list != nil {
+	switch {
+
+	case i.tx != nil:
 
-		i.list.lock.Lock()
-		defer i.list.lock.Unlock()
+		if i.tx.write {
+			i.tx.tree.Delete(i)
+			i.tx.events = append(i.tx.events, Event{Op: Del, Ver: i.ver, Val: i.Val()})
+		}
 
-		i.list.tree.Delete(i)
+	case i.list != nil:
+
+		l := i.list
+
+		l.lock.Lock()
+		defer l.lock.Unlock()
+
+		l.tree.Delete(i)
 
 		i.list = nil
 
+		l.publish(Del, i.ver, i.Val())
+
 	}
 
 	return i
 
 }
 
-// Prev returns the previous item to this item in the list.
+// Prev returns the previous item to this item, within whichever list or
+// transaction snapshot this item was obtained from.
 func (i *Item) Prev() *Item {
 
-	if i.list != nil {
+	switch {
+
+	case i.tx != nil:
+
+		return newTxItem(i.tx, find(i.tx.tree, i.ver, Prev))
+
+	case i.list != nil:
 
 		i.list.lock.RLock()
 		defer i.list.lock.RUnlock()
@@ -78,10 +139,17 @@ func (i *Item) Prev() *Item {
 
 }
 
-// Next returns the next item to this item in the list.
+// Next returns the next item to this item, within whichever list or
+// transaction snapshot this item was obtained from.
 func (i *Item) Next() *Item {
 
-	if i.list != nil {
+	switch {
+
+	case i.tx != nil:
+
+		return newTxItem(i.tx, find(i.tx.tree, i.ver, Next))
+
+	case i.list != nil:
 
 		i.list.lock.RLock()
 		defer i.list.lock.RUnlock()
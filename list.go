@@ -15,12 +15,24 @@
 package blist
 
 import "sync"
+import "errors"
 import "github.com/google/btree"
 
 // List represents an in-memory binary list.
 type List struct {
-	tree *btree.BTree
-	lock sync.RWMutex
+	tree  *btree.BTree
+	lock  sync.RWMutex
+	wlock sync.Mutex
+
+	subs    []*subscriber
+	dropped uint64
+
+	codec Codec
+
+	retention RetentionPolicy
+	gcLock    sync.Mutex
+	gcStop    chan struct{}
+	gcDone    chan struct{}
 }
 
 // Find determines which method is used to seek items in the list.
@@ -48,9 +60,87 @@ const (
 	Nearest
 )
 
+// Option configures a List created via New.
+type Option func(*List)
+
+// WithCodec configures the Codec used to encode and decode item values.
+// The default, used when no WithCodec option is given, is NoopCodec.
+func WithCodec(c Codec) Option {
+	return func(l *List) {
+		l.codec = c
+	}
+}
+
 // New creates a new list
-func New() *List {
-	return &List{tree: btree.New(2)}
+func New(opts ...Option) *List {
+
+	l := &List{tree: btree.New(2)}
+
+	for _, opt := range opts {
+		opt(l)
+	}
+
+	return l
+
+}
+
+// Tx represents a read-only or read-write transaction over a snapshot of
+// the list. See List.View and List.Update.
+type Tx struct {
+	list   *List
+	tree   *btree.BTree
+	write  bool
+	events []Event
+}
+
+// ErrTxNotWritable is returned when a mutating method is called on a
+// transaction started with View.
+var ErrTxNotWritable = errors.New("blist: transaction is not writable")
+
+// View starts a read-only transaction against a point-in-time snapshot of
+// the list. The snapshot is unaffected by any Put, Del, or Exp made by
+// other goroutines once View has begun, and is discarded when fn returns.
+func (l *List) View(fn func(*Tx) error) error {
+
+	l.lock.Lock()
+	tree := l.tree.Clone()
+	l.lock.Unlock()
+
+	return fn(&Tx{list: l, tree: tree})
+
+}
+
+// Update starts a read/write transaction against a point-in-time snapshot
+// of the list. Writers are serialized, so the snapshot reflects the list
+// as it was when Update began. If fn returns an error, all changes made
+// within the transaction are discarded and the list is left untouched.
+// Otherwise, the snapshot is committed back to the list atomically, and
+// every Put, Del, and Exp made within the transaction is published to
+// Watch and WatchRange subscribers, in the order it was made.
+func (l *List) Update(fn func(*Tx) error) error {
+
+	l.wlock.Lock()
+	defer l.wlock.Unlock()
+
+	l.lock.Lock()
+	tree := l.tree.Clone()
+	l.lock.Unlock()
+
+	tx := &Tx{list: l, tree: tree, write: true}
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	l.lock.Lock()
+	l.tree = tx.tree
+	for _, ev := range tx.events {
+		l.publish(ev.Op, ev.Ver, ev.Val)
+	}
+	l.lock.Unlock()
+
+	return nil
+
 }
 
 // Clr clears all of the items from the list.
@@ -71,7 +161,27 @@ func (l *List) Put(ver int64, val []byte) *Item {
 	l.lock.Lock()
 	defer l.lock.Unlock()
 
-	i := &Item{ver: ver, val: val, list: l}
+	i := &Item{ver: ver, list: l, codec: l.codec}
+	i.Set(val)
+
+	l.tree.ReplaceOrInsert(i)
+
+	l.publish(Put, ver, val)
+
+	return i
+
+}
+
+// putRaw inserts an item whose value is already encoded, bypassing the
+// list's Codec. It is used to load values that were already persisted in
+// their on-wire form, such as a Store's snapshot, without a spurious
+// decode/re-encode round trip.
+func (l *List) putRaw(ver int64, raw []byte) *Item {
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	i := &Item{ver: ver, raw: raw, codec: l.codec, list: l}
 
 	l.tree.ReplaceOrInsert(i)
 
@@ -94,6 +204,8 @@ func (l *List) Del(ver int64, meth Find) *Item {
 
 		i.list = nil
 
+		l.publish(Del, i.ver, i.Val())
+
 	}
 
 	return i
@@ -112,7 +224,9 @@ func (l *List) Exp(ver int64, meth Find) *Item {
 	if i != nil {
 
 		l.tree.DescendLessOrEqual(i, func(v btree.Item) bool {
-			l.tree.Delete(v.(*Item))
+			item := v.(*Item)
+			l.tree.Delete(item)
+			l.publish(Exp, item.ver, item.Val())
 			return true
 		})
 
@@ -193,12 +307,18 @@ func (l *List) Walk(fn func(*Item) bool) {
 // ---------------------------------------------------------------------------
 
 func (l *List) find(ver int64, what Find) (i *Item) {
+	return find(l.tree, ver, what)
+}
+
+// find seeks an item in tree according to what, the shared implementation
+// used by both List and Tx.
+func find(tree *btree.BTree, ver int64, what Find) (i *Item) {
 
 	switch what {
 
 	case Prev: // Get the item below the specified version
 
-		l.tree.DescendLessOrEqual(&Item{ver: ver}, func(v btree.Item) bool {
+		tree.DescendLessOrEqual(&Item{ver: ver}, func(v btree.Item) bool {
 			if v.(*Item).ver != ver {
 				i = v.(*Item)
 				return false
@@ -208,7 +328,7 @@ func (l *List) find(ver int64, what Find) (i *Item) {
 
 	case Next: // Get the item above the specified version
 
-		l.tree.AscendGreaterOrEqual(&Item{ver: ver}, func(v btree.Item) bool {
+		tree.AscendGreaterOrEqual(&Item{ver: ver}, func(v btree.Item) bool {
 			if v.(*Item).ver != ver {
 				i = v.(*Item)
 				return false
@@ -218,21 +338,21 @@ func (l *List) find(ver int64, what Find) (i *Item) {
 
 	case Upto: // Get the item up to the specified version
 
-		l.tree.DescendLessOrEqual(&Item{ver: ver}, func(v btree.Item) bool {
+		tree.DescendLessOrEqual(&Item{ver: ver}, func(v btree.Item) bool {
 			i = v.(*Item)
 			return false
 		})
 
 	case Exact: // Get the exact specified version
 
-		if v := l.tree.Get(&Item{ver: ver}); v != nil {
+		if v := tree.Get(&Item{ver: ver}); v != nil {
 			i = v.(*Item)
 		}
 
 	case Nearest: // Get the item nearest the specified version
 
-		if i = l.find(ver, Upto); i == nil {
-			i = l.find(ver, Next)
+		if i = find(tree, ver, Upto); i == nil {
+			i = find(tree, ver, Next)
 		}
 
 	}
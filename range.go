@@ -0,0 +1,121 @@
+// Copyright © SurrealDB Ltd
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blist
+
+import "github.com/google/btree"
+
+// Bound specifies whether the from and to versions passed to Range,
+// RangeReverse, and Between are included in, or excluded from, the
+// resulting range. The default, the zero value, includes both boundaries.
+type Bound struct {
+	ExclFrom bool
+	ExclTo   bool
+}
+
+// Range iterates over the list for every item with a version between the
+// specified from and to versions, in ascending order, until the range
+// function returns true. By default both boundaries are included; pass a
+// Bound to exclude either end.
+func (l *List) Range(from, to int64, fn func(*Item) bool, bound ...Bound) {
+
+	l.lock.RLock()
+	defer l.lock.RUnlock()
+
+	greaterOrEqual, lessThan := rangeBounds(from, to, bound)
+
+	l.tree.AscendRange(greaterOrEqual, lessThan, func(i btree.Item) bool {
+		return !fn(i.(*Item))
+	})
+
+}
+
+// RangeReverse iterates over the list for every item with a version
+// between the specified from and to versions, in descending order, until
+// the range function returns true. By default both boundaries are
+// included; pass a Bound to exclude either end.
+func (l *List) RangeReverse(from, to int64, fn func(*Item) bool, bound ...Bound) {
+
+	l.lock.RLock()
+	defer l.lock.RUnlock()
+
+	lessOrEqual, greaterThan := rangeBoundsDesc(from, to, bound)
+
+	l.tree.DescendRange(lessOrEqual, greaterThan, func(i btree.Item) bool {
+		return !fn(i.(*Item))
+	})
+
+}
+
+// Between returns a slice of all items in the list with a version between
+// the specified from and to versions. By default both boundaries are
+// included; pass a Bound to exclude either end.
+func (l *List) Between(from, to int64, bound ...Bound) (out []*Item) {
+
+	l.Range(from, to, func(i *Item) bool {
+		out = append(out, i)
+		return false
+	}, bound...)
+
+	return
+
+}
+
+// rangeBounds converts an inclusive from/to pair, and an optional Bound,
+// into the half-open [greaterOrEqual, lessThan) pair that btree's
+// AscendRange expects.
+func rangeBounds(from, to int64, bound []Bound) (greaterOrEqual, lessThan *Item) {
+
+	var b Bound
+
+	if len(bound) > 0 {
+		b = bound[0]
+	}
+
+	if b.ExclFrom {
+		from++
+	}
+
+	if !b.ExclTo {
+		to++
+	}
+
+	return &Item{ver: from}, &Item{ver: to}
+
+}
+
+// rangeBoundsDesc converts an inclusive from/to pair, and an optional
+// Bound, into the (lessOrEqual, greaterThan] pair that btree's
+// DescendRange expects. This is not simply rangeBounds' pair reversed:
+// DescendRange's two arguments have opposite inclusive/exclusive meanings
+// from AscendRange's.
+func rangeBoundsDesc(from, to int64, bound []Bound) (lessOrEqual, greaterThan *Item) {
+
+	var b Bound
+
+	if len(bound) > 0 {
+		b = bound[0]
+	}
+
+	if b.ExclTo {
+		to--
+	}
+
+	if !b.ExclFrom {
+		from--
+	}
+
+	return &Item{ver: to}, &Item{ver: from}
+
+}
@@ -0,0 +1,88 @@
+// Copyright © SurrealDB Ltd
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blist
+
+import (
+	"reflect"
+	"testing"
+)
+
+func seedList(t *testing.T, versions ...int64) *List {
+	t.Helper()
+	l := New()
+	for _, v := range versions {
+		l.Put(v, nil)
+	}
+	return l
+}
+
+func vers(items []*Item) (out []int64) {
+	for _, i := range items {
+		out = append(out, i.Ver())
+	}
+	return
+}
+
+func TestRangeAscending(t *testing.T) {
+
+	l := seedList(t, 10, 20, 30, 40, 41, 50)
+
+	got := l.Between(20, 40)
+
+	want := []int64{20, 30, 40}
+
+	if !reflect.DeepEqual(vers(got), want) {
+		t.Fatalf("Between(20, 40) = %v, want %v", vers(got), want)
+	}
+
+}
+
+func TestRangeReverse(t *testing.T) {
+
+	l := seedList(t, 10, 20, 30, 40, 41, 50)
+
+	var got []int64
+
+	l.RangeReverse(20, 40, func(i *Item) bool {
+		got = append(got, i.Ver())
+		return false
+	})
+
+	want := []int64{40, 30, 20}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("RangeReverse(20, 40) = %v, want %v", got, want)
+	}
+
+}
+
+func TestRangeReverseExclusiveBounds(t *testing.T) {
+
+	l := seedList(t, 10, 20, 30, 40, 41, 50)
+
+	var got []int64
+
+	l.RangeReverse(20, 40, func(i *Item) bool {
+		got = append(got, i.Ver())
+		return false
+	}, Bound{ExclFrom: true, ExclTo: true})
+
+	want := []int64{30}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("RangeReverse(20, 40, excl/excl) = %v, want %v", got, want)
+	}
+
+}
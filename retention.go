@@ -0,0 +1,176 @@
+// Copyright © SurrealDB Ltd
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blist
+
+import (
+	"time"
+
+	"github.com/google/btree"
+)
+
+// RetentionPolicy declaratively bounds how many versions, and how old a
+// version, a List retains. It is enforced by Compact, and periodically by
+// the background goroutine started with StartGC.
+type RetentionPolicy struct {
+	// MaxVersions keeps at most the MaxVersions newest items, dropping
+	// older items once exceeded. Zero means unbounded.
+	MaxVersions int
+	// MaxAge drops any item whose version is older than MaxAge, relative
+	// to NowFn. Zero means unbounded.
+	MaxAge time.Duration
+	// MinKeep never drops an item if doing so would leave fewer than
+	// MinKeep items in the list.
+	MinKeep int
+	// NowFn returns the current version, used to evaluate MaxAge. It
+	// defaults to time.Now().UnixNano() if not set.
+	NowFn func() int64
+}
+
+func (p RetentionPolicy) now() int64 {
+	if p.NowFn != nil {
+		return p.NowFn()
+	}
+	return time.Now().UnixNano()
+}
+
+// SetRetention configures the RetentionPolicy enforced by Compact, and by
+// the background goroutine started with StartGC.
+func (l *List) SetRetention(p RetentionPolicy) {
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.retention = p
+
+}
+
+// Compact enforces the current RetentionPolicy once, synchronously,
+// dropping the oldest items that exceed MaxVersions or MaxAge, but never
+// dropping below MinKeep items.
+func (l *List) Compact() {
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	p := l.retention
+
+	if p.MaxVersions <= 0 && p.MaxAge <= 0 {
+		return
+	}
+
+	min := p.MinKeep
+	if min < 0 {
+		min = 0
+	}
+
+	var cutoff int64
+	var hasCutoff bool
+
+	if p.MaxAge > 0 {
+		cutoff = p.now() - int64(p.MaxAge)
+		hasCutoff = true
+	}
+
+	remaining := l.tree.Len()
+
+	var drop []*Item
+
+	l.tree.Ascend(func(v btree.Item) bool {
+
+		if remaining <= min {
+			return false
+		}
+
+		item := v.(*Item)
+
+		overCount := p.MaxVersions > 0 && remaining > p.MaxVersions
+		overAge := hasCutoff && item.ver < cutoff
+
+		if !overCount && !overAge {
+			return false
+		}
+
+		drop = append(drop, item)
+		remaining--
+
+		return true
+
+	})
+
+	for _, item := range drop {
+		l.tree.Delete(item)
+		item.list = nil
+		l.publish(Exp, item.ver, item.Val())
+	}
+
+}
+
+// StartGC starts a background goroutine that calls Compact every
+// interval, enforcing the current RetentionPolicy. It is a no-op if a GC
+// goroutine is already running.
+func (l *List) StartGC(interval time.Duration) {
+
+	l.gcLock.Lock()
+	defer l.gcLock.Unlock()
+
+	if l.gcStop != nil {
+		return
+	}
+
+	l.gcStop = make(chan struct{})
+	l.gcDone = make(chan struct{})
+
+	go l.gcLoop(l.gcStop, l.gcDone, interval)
+
+}
+
+// StopGC stops the background goroutine started by StartGC. It is a
+// no-op if no GC goroutine is running.
+func (l *List) StopGC() {
+
+	l.gcLock.Lock()
+
+	if l.gcStop == nil {
+		l.gcLock.Unlock()
+		return
+	}
+
+	close(l.gcStop)
+	done := l.gcDone
+	l.gcStop, l.gcDone = nil, nil
+
+	l.gcLock.Unlock()
+
+	<-done
+
+}
+
+func (l *List) gcLoop(stop, done chan struct{}, interval time.Duration) {
+
+	defer close(done)
+
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-t.C:
+			l.Compact()
+		case <-stop:
+			return
+		}
+	}
+
+}
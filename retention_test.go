@@ -0,0 +1,111 @@
+// Copyright © SurrealDB Ltd
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blist
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCompactMaxVersions(t *testing.T) {
+
+	l := seedList(t, 10, 20, 30, 40, 50)
+
+	l.SetRetention(RetentionPolicy{MaxVersions: 2})
+
+	l.Compact()
+
+	got := vers(l.Between(0, 100))
+	want := []int64{40, 50}
+
+	if !equalVers(got, want) {
+		t.Fatalf("after Compact, versions = %v, want %v", got, want)
+	}
+
+}
+
+func TestCompactMaxAge(t *testing.T) {
+
+	l := seedList(t, 10, 20, 30, 40, 50)
+
+	l.SetRetention(RetentionPolicy{
+		MaxAge: 15,
+		NowFn:  func() int64 { return 50 },
+	})
+
+	l.Compact()
+
+	got := vers(l.Between(0, 100))
+	want := []int64{40, 50}
+
+	if !equalVers(got, want) {
+		t.Fatalf("after Compact, versions = %v, want %v", got, want)
+	}
+
+}
+
+func TestCompactRespectsMinKeep(t *testing.T) {
+
+	l := seedList(t, 10, 20, 30)
+
+	l.SetRetention(RetentionPolicy{MaxVersions: 1, MinKeep: 2})
+
+	l.Compact()
+
+	got := vers(l.Between(0, 100))
+	want := []int64{20, 30}
+
+	if !equalVers(got, want) {
+		t.Fatalf("after Compact, versions = %v, want %v (MinKeep should win over MaxVersions)", got, want)
+	}
+
+}
+
+func TestStartStopGC(t *testing.T) {
+
+	l := seedList(t, 10, 20, 30, 40, 50)
+
+	l.SetRetention(RetentionPolicy{MaxVersions: 2})
+
+	l.StartGC(5 * time.Millisecond)
+	defer l.StopGC()
+
+	deadline := time.Now().Add(time.Second)
+
+	for l.Len() != 2 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if l.Len() != 2 {
+		t.Fatalf("list has %d items after StartGC, want 2", l.Len())
+	}
+
+}
+
+func equalVers(a, b []int64) bool {
+
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+
+}
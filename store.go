@@ -0,0 +1,464 @@
+// Copyright © SurrealDB Ltd
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blist
+
+import (
+	"encoding/binary"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// SyncPolicy determines how often a Store flushes its write-ahead log to
+// stable storage.
+type SyncPolicy int8
+
+const (
+	// Never never fsyncs the WAL; durability is left entirely to the
+	// operating system's own page cache flushing.
+	Never SyncPolicy = iota
+	// EveryWrite fsyncs the WAL after every Put, Del, or Exp.
+	EveryWrite
+	// EveryN fsyncs the WAL after every N writes.
+	EveryN
+	// Interval fsyncs the WAL on a fixed time interval, in a background
+	// goroutine.
+	Interval
+)
+
+const (
+	opPut byte = iota
+	opDel
+	opExp
+)
+
+// Store wraps a List with an append-only write-ahead log and sharded
+// on-disk snapshots, so that its contents survive a process restart.
+type Store struct {
+	*List
+
+	dir string
+	wal *os.File
+
+	policy SyncPolicy
+	every  int
+	period time.Duration
+
+	mu     sync.Mutex
+	writes int
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// Open opens the store rooted at path, creating it if it does not yet
+// exist. It loads the most recent snapshot, then replays the WAL written
+// since that snapshot, stopping at the first record with a bad checksum
+// and treating everything after it as a torn write left by a crash.
+func Open(path string) (*Store, error) {
+
+	if err := os.MkdirAll(path, 0o755); err != nil {
+		return nil, err
+	}
+
+	s := &Store{
+		List:   New(),
+		dir:    path,
+		policy: Never,
+	}
+
+	if err := s.loadSnapshot(); err != nil {
+		return nil, err
+	}
+
+	wal, err := os.OpenFile(s.walPath(), os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.replayWAL(wal); err != nil {
+		wal.Close()
+		return nil, err
+	}
+
+	if _, err := wal.Seek(0, io.SeekEnd); err != nil {
+		wal.Close()
+		return nil, err
+	}
+
+	s.wal = wal
+
+	return s, nil
+
+}
+
+// SetSyncPolicy configures how the store flushes its WAL to disk. every is
+// only meaningful for EveryN, and period is only meaningful for Interval.
+// Changing the policy stops any previously running interval goroutine.
+func (s *Store) SetSyncPolicy(policy SyncPolicy, every int, period time.Duration) {
+
+	s.mu.Lock()
+
+	if s.stop != nil {
+		close(s.stop)
+		done := s.done
+		s.stop, s.done = nil, nil
+		s.mu.Unlock()
+		<-done
+		s.mu.Lock()
+	}
+
+	s.policy, s.every, s.period = policy, every, period
+
+	if policy == Interval && period > 0 {
+		s.stop = make(chan struct{})
+		s.done = make(chan struct{})
+		go s.syncLoop(s.stop, s.done, period)
+	}
+
+	s.mu.Unlock()
+
+}
+
+func (s *Store) syncLoop(stop, done chan struct{}, period time.Duration) {
+
+	defer close(done)
+
+	t := time.NewTicker(period)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-t.C:
+			s.Sync()
+		case <-stop:
+			return
+		}
+	}
+
+}
+
+// Put appends a PUT record to the WAL before inserting val into the
+// underlying list.
+func (s *Store) Put(ver int64, val []byte) (*Item, error) {
+
+	if err := s.append(opPut, ver, val); err != nil {
+		return nil, err
+	}
+
+	return s.List.Put(ver, val), nil
+
+}
+
+// Del resolves the item that meth selects, then appends a DEL record for
+// its exact version to the WAL before deleting it from the underlying list.
+func (s *Store) Del(ver int64, meth Find) (*Item, error) {
+
+	i := s.List.Get(ver, meth)
+	if i == nil {
+		return nil, nil
+	}
+
+	if err := s.append(opDel, i.ver, nil); err != nil {
+		return nil, err
+	}
+
+	return s.List.Del(i.ver, Exact), nil
+
+}
+
+// Exp resolves the item that meth selects, then appends an EXP record for
+// its exact version to the WAL before expunging items from the underlying
+// list.
+func (s *Store) Exp(ver int64, meth Find) (*Item, error) {
+
+	i := s.List.Get(ver, meth)
+	if i == nil {
+		return nil, nil
+	}
+
+	if err := s.append(opExp, i.ver, nil); err != nil {
+		return nil, err
+	}
+
+	return s.List.Exp(i.ver, Exact), nil
+
+}
+
+// Sync fsyncs the WAL, regardless of the configured SyncPolicy.
+func (s *Store) Sync() error {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.wal.Sync()
+
+}
+
+// Snapshot writes every item in the list to its sharded on-disk location
+// under path, removes the files of items no longer in the list, then
+// truncates the WAL, since its contents are now captured by the snapshot.
+func (s *Store) Snapshot() error {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var err error
+
+	s.List.Walk(func(i *Item) bool {
+		if werr := s.writeItem(i); werr != nil {
+			err = werr
+			return true
+		}
+		return false
+	})
+
+	if err != nil {
+		return err
+	}
+
+	files, err := filepath.Glob(filepath.Join(s.dir, "snap", "*", "*"))
+	if err != nil {
+		return err
+	}
+
+	for _, f := range files {
+		ver, perr := strconv.ParseInt(filepath.Base(f), 10, 64)
+		if perr == nil && s.List.Get(ver, Exact) == nil {
+			if err := os.Remove(f); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := s.wal.Truncate(0); err != nil {
+		return err
+	}
+
+	if _, err := s.wal.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	return s.wal.Sync()
+
+}
+
+// Close stops any background sync goroutine and closes the WAL file.
+func (s *Store) Close() error {
+
+	s.SetSyncPolicy(Never, 0, 0)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.wal.Close()
+
+}
+
+// ---------------------------------------------------------------------------
+
+func (s *Store) walPath() string {
+	return filepath.Join(s.dir, "wal.log")
+}
+
+// shardDir places an item's on-disk file in one of 256 subdirectories,
+// keyed on the low byte of its version, diskv-style, so that no single
+// directory grows large enough to make a snapshot rewrite expensive.
+func (s *Store) shardDir(ver int64) string {
+	return filepath.Join(s.dir, "snap", strconv.Itoa(int(uint8(ver))))
+}
+
+func (s *Store) itemPath(ver int64) string {
+	return filepath.Join(s.shardDir(ver), strconv.FormatInt(ver, 10))
+}
+
+// writeItem writes a single item to its sharded snapshot location, as a
+// length-prefixed record followed by its CRC32 checksum.
+func (s *Store) writeItem(i *Item) error {
+
+	ver := i.ver
+	raw := i.RawVal()
+
+	if err := os.MkdirAll(s.shardDir(ver), 0o755); err != nil {
+		return err
+	}
+
+	body := make([]byte, 4+len(raw))
+	binary.BigEndian.PutUint32(body, uint32(len(raw)))
+	copy(body[4:], raw)
+
+	rec := make([]byte, len(body)+4)
+	copy(rec, body)
+	binary.BigEndian.PutUint32(rec[len(body):], crc32.ChecksumIEEE(body))
+
+	return os.WriteFile(s.itemPath(ver), rec, 0o644)
+
+}
+
+// loadSnapshot walks every shard directory and loads each item file into
+// the in-memory list, skipping any file that fails its checksum.
+func (s *Store) loadSnapshot() error {
+
+	root := filepath.Join(s.dir, "snap")
+
+	shards, err := os.ReadDir(root)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	for _, shard := range shards {
+
+		if !shard.IsDir() {
+			continue
+		}
+
+		shardPath := filepath.Join(root, shard.Name())
+
+		files, err := os.ReadDir(shardPath)
+		if err != nil {
+			return err
+		}
+
+		for _, f := range files {
+
+			ver, err := strconv.ParseInt(f.Name(), 10, 64)
+			if err != nil {
+				continue
+			}
+
+			rec, err := os.ReadFile(filepath.Join(shardPath, f.Name()))
+			if err != nil {
+				return err
+			}
+
+			if len(rec) < 8 {
+				continue
+			}
+
+			body, crc := rec[:len(rec)-4], rec[len(rec)-4:]
+
+			if crc32.ChecksumIEEE(body) != binary.BigEndian.Uint32(crc) {
+				continue
+			}
+
+			if n := binary.BigEndian.Uint32(body); int(n) != len(body)-4 {
+				continue
+			}
+
+			s.List.putRaw(ver, body[4:])
+
+		}
+
+	}
+
+	return nil
+
+}
+
+// append writes a single WAL record for op, then fsyncs according to the
+// configured SyncPolicy.
+func (s *Store) append(op byte, ver int64, val []byte) error {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	body := make([]byte, 1+8+4+len(val))
+	body[0] = op
+	binary.BigEndian.PutUint64(body[1:9], uint64(ver))
+	binary.BigEndian.PutUint32(body[9:13], uint32(len(val)))
+	copy(body[13:], val)
+
+	rec := make([]byte, len(body)+4)
+	copy(rec, body)
+	binary.BigEndian.PutUint32(rec[len(body):], crc32.ChecksumIEEE(body))
+
+	if _, err := s.wal.Write(rec); err != nil {
+		return err
+	}
+
+	s.writes++
+
+	switch s.policy {
+	case EveryWrite:
+		return s.wal.Sync()
+	case EveryN:
+		if s.every > 0 && s.writes >= s.every {
+			s.writes = 0
+			return s.wal.Sync()
+		}
+	}
+
+	return nil
+
+}
+
+// replayWAL reads every record from wal in order, applying each to the
+// in-memory list, and stops at the first record with a bad checksum,
+// treating the remainder of the file as a torn write left by a crash.
+func (s *Store) replayWAL(wal *os.File) error {
+
+	if _, err := wal.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	for {
+
+		header := make([]byte, 13)
+
+		if _, err := io.ReadFull(wal, header); err != nil {
+			return nil
+		}
+
+		n := binary.BigEndian.Uint32(header[9:13])
+
+		body := make([]byte, 13+int(n))
+		copy(body, header)
+
+		if _, err := io.ReadFull(wal, body[13:]); err != nil {
+			return nil
+		}
+
+		crc := make([]byte, 4)
+
+		if _, err := io.ReadFull(wal, crc); err != nil {
+			return nil
+		}
+
+		if crc32.ChecksumIEEE(body) != binary.BigEndian.Uint32(crc) {
+			return nil
+		}
+
+		op := body[0]
+		ver := int64(binary.BigEndian.Uint64(body[1:9]))
+		val := body[13:]
+
+		switch op {
+		case opPut:
+			s.List.Put(ver, val)
+		case opDel:
+			s.List.Del(ver, Exact)
+		case opExp:
+			s.List.Exp(ver, Exact)
+		}
+
+	}
+
+}
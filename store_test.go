@@ -0,0 +1,160 @@
+// Copyright © SurrealDB Ltd
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blist
+
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+
+// TestLoadSnapshotDoesNotReencode guards against loadSnapshot feeding
+// already-encoded bytes back through the list's Codec a second time,
+// which would silently corrupt every value on restart whenever a
+// compressing Codec is configured.
+func TestLoadSnapshotDoesNotReencode(t *testing.T) {
+
+	dir := t.TempDir()
+
+	codec := SnappyCodec{}
+	plain := []byte("hello world hello world hello world")
+
+	writer := &Store{dir: dir, List: New(WithCodec(codec))}
+
+	item := &Item{ver: 7, codec: codec}
+	item.Set(plain)
+
+	if err := writer.writeItem(item); err != nil {
+		t.Fatal(err)
+	}
+
+	reader := &Store{dir: dir, List: New(WithCodec(codec))}
+
+	if err := reader.loadSnapshot(); err != nil {
+		t.Fatal(err)
+	}
+
+	got := reader.List.Get(7, Exact)
+	if got == nil {
+		t.Fatal("item not loaded from snapshot")
+	}
+
+	if !bytes.Equal(got.RawVal(), item.RawVal()) {
+		t.Fatalf("RawVal() = %x, want %x (unchanged, single-encoded, bytes)", got.RawVal(), item.RawVal())
+	}
+
+	if !bytes.Equal(got.Val(), plain) {
+		t.Fatalf("Val() = %q, want %q (got double-encoded value)", got.Val(), plain)
+	}
+
+}
+
+func TestOpenPersistsAcrossRestart(t *testing.T) {
+
+	dir := t.TempDir()
+
+	s, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.Put(1, []byte("a")); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.Put(2, []byte("b")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Sync(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	s2, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s2.Close()
+
+	if got := s2.Get(1, Exact); got == nil || string(got.Val()) != "a" {
+		t.Fatalf("version 1 after reopen = %v, want %q", got, "a")
+	}
+
+	if got := s2.Get(2, Exact); got == nil || string(got.Val()) != "b" {
+		t.Fatalf("version 2 after reopen = %v, want %q", got, "b")
+	}
+
+}
+
+// TestReplayWALStopsAtBadCRC guards replayWAL's torn-write handling: a WAL
+// record corrupted by a crash mid-write must stop replay at that record,
+// without losing the valid records that precede it.
+func TestReplayWALStopsAtBadCRC(t *testing.T) {
+
+	dir := t.TempDir()
+
+	s, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.Put(1, []byte("a")); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.Put(2, []byte("b")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Sync(); err != nil {
+		t.Fatal(err)
+	}
+
+	walPath := s.walPath()
+
+	if err := s.wal.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	raw, err := os.ReadFile(walPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	raw[len(raw)-1] ^= 0xff // flip a bit in the second record's checksum
+
+	if err := os.WriteFile(walPath, raw, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	s2, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s2.Close()
+
+	if got := s2.Get(1, Exact); got == nil || string(got.Val()) != "a" {
+		t.Fatalf("version 1 = %v, want %q (record before the corruption)", got, "a")
+	}
+
+	if got := s2.Get(2, Exact); got != nil {
+		t.Fatalf("version 2 = %v, want nil (record after the corruption should be dropped)", got)
+	}
+
+}
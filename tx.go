@@ -0,0 +1,177 @@
+// Copyright © SurrealDB Ltd
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blist
+
+import "github.com/google/btree"
+
+// newTxItem returns a copy of src bound to tx, so that Prev, Next, and Del
+// called on the result operate against the transaction's own snapshot
+// instead of reaching through to the list it may still be shared with.
+// This is necessary because btree.Clone is copy-on-write at the node
+// level only: an item untouched since the snapshot was taken is the very
+// same *Item value stored in the live list, and would otherwise still
+// carry that list's affinity.
+func newTxItem(tx *Tx, src *Item) *Item {
+
+	if src == nil {
+		return nil
+	}
+
+	return &Item{ver: src.ver, raw: src.raw, dec: src.dec, codec: src.codec, tx: tx}
+
+}
+
+// Put inserts a new item into the transaction's snapshot, ensuring that it
+// is sorted after insertion. If an item with the same version already
+// exists, then the value is updated. Put returns ErrTxNotWritable if
+// called within a transaction started with View.
+func (tx *Tx) Put(ver int64, val []byte) (*Item, error) {
+
+	if !tx.write {
+		return nil, ErrTxNotWritable
+	}
+
+	i := &Item{ver: ver, codec: tx.list.codec, tx: tx}
+	i.Set(val)
+
+	tx.tree.ReplaceOrInsert(i)
+
+	tx.events = append(tx.events, Event{Op: Put, Ver: ver, Val: val})
+
+	return i, nil
+
+}
+
+// Del deletes a specific item from the transaction's snapshot, returning
+// the previous item if it existed.
+func (tx *Tx) Del(ver int64, meth Find) (*Item, error) {
+
+	if !tx.write {
+		return nil, ErrTxNotWritable
+	}
+
+	i := find(tx.tree, ver, meth)
+
+	if i != nil {
+		tx.tree.Delete(i)
+		tx.events = append(tx.events, Event{Op: Del, Ver: i.ver, Val: i.Val()})
+	}
+
+	return newTxItem(tx, i), nil
+
+}
+
+// Exp expunges all items in the transaction's snapshot, upto and including
+// the specified version, returning the latest version, or a nil value if
+// not found.
+func (tx *Tx) Exp(ver int64, meth Find) (*Item, error) {
+
+	if !tx.write {
+		return nil, ErrTxNotWritable
+	}
+
+	i := find(tx.tree, ver, meth)
+
+	if i != nil {
+
+		tx.tree.DescendLessOrEqual(i, func(v btree.Item) bool {
+			item := v.(*Item)
+			tx.tree.Delete(item)
+			tx.events = append(tx.events, Event{Op: Exp, Ver: item.ver, Val: item.Val()})
+			return true
+		})
+
+	}
+
+	return newTxItem(tx, i), nil
+
+}
+
+// Get gets a specific item from the transaction's snapshot. If the exact
+// item does not exist, then a nil value is returned.
+func (tx *Tx) Get(ver int64, meth Find) *Item {
+	return newTxItem(tx, find(tx.tree, ver, meth))
+}
+
+// Len returns the total number of items in the transaction's snapshot.
+func (tx *Tx) Len() int {
+	return tx.tree.Len()
+}
+
+// Min returns the first item in the transaction's snapshot.
+func (tx *Tx) Min() *Item {
+	if i := tx.tree.Min(); i != nil {
+		return newTxItem(tx, i.(*Item))
+	}
+	return nil
+}
+
+// Max returns the last item in the transaction's snapshot.
+func (tx *Tx) Max() *Item {
+	if i := tx.tree.Max(); i != nil {
+		return newTxItem(tx, i.(*Item))
+	}
+	return nil
+}
+
+// Walk iterates over the transaction's snapshot starting at the first
+// version, and continuing until the walk function returns true.
+func (tx *Tx) Walk(fn func(*Item) bool) {
+	tx.tree.Ascend(func(i btree.Item) bool {
+		return !fn(newTxItem(tx, i.(*Item)))
+	})
+}
+
+// Range iterates over the transaction's snapshot for every item with a
+// version between the specified from and to versions, in ascending order,
+// until the range function returns true. By default both boundaries are
+// included; pass a Bound to exclude either end.
+func (tx *Tx) Range(from, to int64, fn func(*Item) bool, bound ...Bound) {
+
+	greaterOrEqual, lessThan := rangeBounds(from, to, bound)
+
+	tx.tree.AscendRange(greaterOrEqual, lessThan, func(i btree.Item) bool {
+		return !fn(newTxItem(tx, i.(*Item)))
+	})
+
+}
+
+// RangeReverse iterates over the transaction's snapshot for every item
+// with a version between the specified from and to versions, in
+// descending order, until the range function returns true. By default
+// both boundaries are included; pass a Bound to exclude either end.
+func (tx *Tx) RangeReverse(from, to int64, fn func(*Item) bool, bound ...Bound) {
+
+	lessOrEqual, greaterThan := rangeBoundsDesc(from, to, bound)
+
+	tx.tree.DescendRange(lessOrEqual, greaterThan, func(i btree.Item) bool {
+		return !fn(newTxItem(tx, i.(*Item)))
+	})
+
+}
+
+// Between returns a slice of all items in the transaction's snapshot with
+// a version between the specified from and to versions. By default both
+// boundaries are included; pass a Bound to exclude either end.
+func (tx *Tx) Between(from, to int64, bound ...Bound) (out []*Item) {
+
+	tx.Range(from, to, func(i *Item) bool {
+		out = append(out, i)
+		return false
+	}, bound...)
+
+	return
+
+}
@@ -0,0 +1,229 @@
+// Copyright © SurrealDB Ltd
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blist
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestViewSnapshotIsolation(t *testing.T) {
+
+	l := New()
+	l.Put(1, []byte("a"))
+
+	err := l.View(func(tx *Tx) error {
+
+		l.Put(2, []byte("b"))
+
+		if tx.Len() != 1 {
+			t.Fatalf("View snapshot saw %d items, want 1", tx.Len())
+		}
+
+		return nil
+
+	})
+
+	if err != nil {
+		t.Fatalf("View returned error: %v", err)
+	}
+
+	if l.Len() != 2 {
+		t.Fatalf("list has %d items after Put, want 2", l.Len())
+	}
+
+}
+
+func TestUpdateCommit(t *testing.T) {
+
+	l := New()
+
+	err := l.Update(func(tx *Tx) error {
+		_, err := tx.Put(1, []byte("a"))
+		return err
+	})
+
+	if err != nil {
+		t.Fatalf("Update returned error: %v", err)
+	}
+
+	if got := l.Get(1, Exact); got == nil || string(got.Val()) != "a" {
+		t.Fatalf("committed item missing or wrong, got %v", got)
+	}
+
+}
+
+// TestViewItemDelIsNoOp guards against an item fetched via tx.Get inside a
+// read-only View reaching through to the live list: Del on such an item
+// must leave the live list untouched, since the item is bound to the
+// View's own snapshot, not the list itself.
+func TestViewItemDelIsNoOp(t *testing.T) {
+
+	l := New()
+	l.Put(1, []byte("a"))
+
+	err := l.View(func(tx *Tx) error {
+
+		i := tx.Get(1, Exact)
+		if i == nil {
+			t.Fatal("tx.Get returned nil")
+		}
+
+		i.Del()
+
+		if tx.Len() != 1 {
+			t.Fatalf("tx snapshot has %d items after Del, want 1 (View is read-only)", tx.Len())
+		}
+
+		return nil
+
+	})
+
+	if err != nil {
+		t.Fatalf("View returned error: %v", err)
+	}
+
+	if l.Len() != 1 {
+		t.Fatalf("list has %d items after View, want 1 (Del on a View item must not touch the live list)", l.Len())
+	}
+
+	if got := l.Get(1, Exact); got == nil {
+		t.Fatal("item missing from the live list after View, want it untouched")
+	}
+
+}
+
+// TestUpdateItemDelCommits guards against the opposite failure: deleting
+// an item fetched via tx.Get inside an Update must participate in the
+// transaction, so the deletion survives the commit instead of being
+// silently reverted when the transaction's tree is swapped back in.
+func TestUpdateItemDelCommits(t *testing.T) {
+
+	l := New()
+	l.Put(1, []byte("a"))
+	l.Put(2, []byte("b"))
+
+	err := l.Update(func(tx *Tx) error {
+
+		i := tx.Get(1, Exact)
+		if i == nil {
+			t.Fatal("tx.Get returned nil")
+		}
+
+		i.Del()
+
+		if tx.Len() != 1 {
+			t.Fatalf("tx snapshot has %d items after Del, want 1", tx.Len())
+		}
+
+		return nil
+
+	})
+
+	if err != nil {
+		t.Fatalf("Update returned error: %v", err)
+	}
+
+	if l.Len() != 1 {
+		t.Fatalf("list has %d items after Update, want 1 (Del must survive commit)", l.Len())
+	}
+
+	if got := l.Get(1, Exact); got != nil {
+		t.Fatal("deleted item still present after commit")
+	}
+
+	if got := l.Get(2, Exact); got == nil {
+		t.Fatal("item 2 missing after commit, want it untouched")
+	}
+
+}
+
+// TestTxItemPrevNext guards against Prev/Next on a tx-sourced item reading
+// through to the live list: both must walk the transaction's own
+// snapshot, which can differ from the live list once other goroutines
+// have mutated it.
+func TestTxItemPrevNext(t *testing.T) {
+
+	l := seedList(t, 10, 20, 30)
+
+	err := l.View(func(tx *Tx) error {
+
+		l.Put(25, []byte("x")) // mutate the live list after the snapshot was taken
+
+		mid := tx.Get(20, Exact)
+		if mid == nil {
+			t.Fatal("tx.Get returned nil")
+		}
+
+		if prev := mid.Prev(); prev == nil || prev.Ver() != 10 {
+			t.Fatalf("Prev() = %v, want version 10", prev)
+		}
+
+		if next := mid.Next(); next == nil || next.Ver() != 30 {
+			t.Fatalf("Next() = %v, want version 30 (snapshot must not see the concurrent Put of 25)", next)
+		}
+
+		return nil
+
+	})
+
+	if err != nil {
+		t.Fatalf("View returned error: %v", err)
+	}
+
+}
+
+// TestConcurrentView exercises View and Update running concurrently from
+// many goroutines. Run with -race: it catches a concurrent-Clone data
+// race on the list's underlying btree.
+func TestConcurrentView(t *testing.T) {
+
+	l := New()
+
+	for i := int64(0); i < 50; i++ {
+		l.Put(i, nil)
+	}
+
+	var wg sync.WaitGroup
+
+	for g := 0; g < 8; g++ {
+
+		wg.Add(1)
+
+		go func(n int64) {
+
+			defer wg.Done()
+
+			for i := 0; i < 20; i++ {
+
+				l.View(func(tx *Tx) error {
+					tx.Len()
+					return nil
+				})
+
+				l.Update(func(tx *Tx) error {
+					_, err := tx.Put(n, nil)
+					return err
+				})
+
+			}
+
+		}(int64(g))
+
+	}
+
+	wg.Wait()
+
+}
@@ -0,0 +1,140 @@
+// Copyright © SurrealDB Ltd
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blist
+
+import "sync/atomic"
+
+// watchBuffer is the size of the per-subscriber buffered channel used by
+// Watch and WatchRange.
+const watchBuffer = 64
+
+// Op identifies the kind of mutation that produced an Event.
+type Op int8
+
+const (
+	// Put indicates that an item was inserted or updated.
+	Put Op = iota
+	// Del indicates that an item was deleted.
+	Del
+	// Exp indicates that an item was expunged.
+	Exp
+)
+
+// Event describes a single mutation published to a Watch or WatchRange
+// subscriber, after it has been applied to the list.
+type Event struct {
+	Op  Op
+	Ver int64
+	Val []byte
+}
+
+// subscriber is a single Watch or WatchRange channel, guarded by the
+// owning list's lock.
+type subscriber struct {
+	ch     chan Event
+	ranged bool
+	from   int64
+	to     int64
+}
+
+// Watch subscribes to every Put, Del, and Exp made to the list, including
+// those committed by Update and those made by Compact. Clr, and Item.Set
+// on an item already in the list, publish no event. The returned channel
+// is closed, and further events are discarded, once cancel is called.
+func (l *List) Watch() (<-chan Event, func()) {
+	return l.watch(false, 0, 0)
+}
+
+// WatchRange subscribes to mutations made to the list for versions between
+// from and to, inclusive of from and exclusive of to.
+func (l *List) WatchRange(from, to int64) (<-chan Event, func()) {
+	return l.watch(true, from, to)
+}
+
+// Dropped returns the total number of events that have been dropped
+// across all subscribers because a subscriber's buffer was full.
+func (l *List) Dropped() uint64 {
+	return atomic.LoadUint64(&l.dropped)
+}
+
+func (l *List) watch(ranged bool, from, to int64) (<-chan Event, func()) {
+
+	sub := &subscriber{
+		ch:     make(chan Event, watchBuffer),
+		ranged: ranged,
+		from:   from,
+		to:     to,
+	}
+
+	l.lock.Lock()
+	l.subs = append(l.subs, sub)
+	l.lock.Unlock()
+
+	cancel := func() {
+
+		l.lock.Lock()
+		defer l.lock.Unlock()
+
+		for i, s := range l.subs {
+			if s == sub {
+				l.subs = append(l.subs[:i], l.subs[i+1:]...)
+				close(sub.ch)
+				return
+			}
+		}
+
+	}
+
+	return sub.ch, cancel
+
+}
+
+// publish notifies every matching subscriber of an event. It must be
+// called with l.lock already held for writing. A slow subscriber has its
+// oldest buffered event dropped, rather than blocking the mutation.
+func (l *List) publish(op Op, ver int64, val []byte) {
+
+	if len(l.subs) == 0 {
+		return
+	}
+
+	ev := Event{Op: op, Ver: ver, Val: val}
+
+	for _, s := range l.subs {
+
+		if s.ranged && (ver < s.from || ver >= s.to) {
+			continue
+		}
+
+		select {
+		case s.ch <- ev:
+			continue
+		default:
+		}
+
+		select {
+		case <-s.ch:
+			atomic.AddUint64(&l.dropped, 1)
+		default:
+		}
+
+		select {
+		case s.ch <- ev:
+		default:
+		}
+
+	}
+
+}
@@ -0,0 +1,99 @@
+// Copyright © SurrealDB Ltd
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blist
+
+import "testing"
+
+func TestWatchReceivesPut(t *testing.T) {
+
+	l := New()
+
+	ch, cancel := l.Watch()
+	defer cancel()
+
+	l.Put(1, []byte("a"))
+
+	ev := <-ch
+
+	if ev.Op != Put || ev.Ver != 1 || string(ev.Val) != "a" {
+		t.Fatalf("got %+v, want Put/1/a", ev)
+	}
+
+}
+
+// TestWatchReceivesItemDel guards against Item.Del, a realistic call path
+// such as list.Get(v, Exact).Del(), mutating the list without publishing
+// an event like every other mutation path does.
+func TestWatchReceivesItemDel(t *testing.T) {
+
+	l := New()
+	l.Put(1, []byte("a"))
+
+	ch, cancel := l.Watch()
+	defer cancel()
+
+	l.Get(1, Exact).Del()
+
+	ev := <-ch
+
+	if ev.Op != Del || ev.Ver != 1 || string(ev.Val) != "a" {
+		t.Fatalf("got %+v, want Del/1/a", ev)
+	}
+
+}
+
+// TestWatchReceivesUpdateCommit guards against transactional mutations
+// being committed without notifying Watch/WatchRange subscribers.
+func TestWatchReceivesUpdateCommit(t *testing.T) {
+
+	l := New()
+
+	ch, cancel := l.Watch()
+	defer cancel()
+
+	err := l.Update(func(tx *Tx) error {
+		if _, err := tx.Put(1, []byte("a")); err != nil {
+			return err
+		}
+		if _, err := tx.Put(2, []byte("b")); err != nil {
+			return err
+		}
+		_, err := tx.Del(1, Exact)
+		return err
+	})
+
+	if err != nil {
+		t.Fatalf("Update returned error: %v", err)
+	}
+
+	var got []Event
+
+	for i := 0; i < 3; i++ {
+		got = append(got, <-ch)
+	}
+
+	want := []Event{
+		{Op: Put, Ver: 1, Val: []byte("a")},
+		{Op: Put, Ver: 2, Val: []byte("b")},
+		{Op: Del, Ver: 1, Val: []byte("a")},
+	}
+
+	for i, ev := range want {
+		if got[i].Op != ev.Op || got[i].Ver != ev.Ver || string(got[i].Val) != string(ev.Val) {
+			t.Fatalf("event %d = %+v, want %+v", i, got[i], ev)
+		}
+	}
+
+}